@@ -0,0 +1,106 @@
+// chunks.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+package pngtext
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ExifData holds the raw EXIF/TIFF payload carried by a PNG eXIf chunk, as defined by the PNG 1.3
+// extension. See also: https://www.w3.org/TR/png-3/#11eXIf
+type ExifData struct {
+	// Raw is the chunk payload exactly as stored, i.e. a TIFF-format EXIF blob.
+	Raw []byte
+}
+
+// Metadata is the result of ParseMetadata: a TextualDataList, as ParseTextualData returns, plus any
+// ancillary chunk decoded by a handler registered via RegisterChunkHandler.
+type Metadata struct {
+	TextualDataList
+
+	// Exif is the parsed eXIf chunk, or nil if the file has none.
+	Exif *ExifData
+	// Time is the parsed tIME chunk, or nil if the file has none.
+	Time *time.Time
+	// Chunks holds the value returned by the registered handler for every non-textual chunk type present
+	// in the file, keyed by chunk type. This includes the built-in "eXIf" and "tIME" entries, which are
+	// also available as Exif and Time for convenience.
+	Chunks map[string]any
+}
+
+// ChunkHandler decodes the payload of a single ancillary PNG chunk, read from r, into a value of the
+// caller's choosing.
+type ChunkHandler func(r io.Reader) (any, error)
+
+var (
+	chunkHandlersMu sync.Mutex
+	chunkHandlers   = map[string]ChunkHandler{
+		"eXIf": decodeExifChunk,
+		"tIME": decodeTimeChunk,
+	}
+)
+
+// RegisterChunkHandler registers h to decode ancillary chunks of the given 4-byte chunkType, so that
+// ParseMetadata surfaces them through Metadata.Chunks. Registering a handler for a chunk type pngtext
+// already understands, such as "eXIf" or "tIME", replaces the built-in one. RegisterChunkHandler is safe
+// to call concurrently with ParseMetadata; a parse already in progress uses the new handler for the
+// chunks it reads after the call.
+func RegisterChunkHandler(chunkType string, h ChunkHandler) {
+	chunkHandlersMu.Lock()
+	defer chunkHandlersMu.Unlock()
+	chunkHandlers[chunkType] = h
+}
+
+func chunkHandler(chunkType string) (ChunkHandler, bool) {
+	chunkHandlersMu.Lock()
+	defer chunkHandlersMu.Unlock()
+	h, ok := chunkHandlers[chunkType]
+	return h, ok
+}
+
+// ParseMetadata reads PNG data from the given reader, as ParseTextualData does, and additionally decodes
+// any ancillary chunk for which a handler has been registered via RegisterChunkHandler, such as the
+// built-in eXIf and tIME chunks.
+func ParseMetadata(r io.Reader) (*Metadata, error) {
+	return ParseMetadataWithOptions(r, Options{})
+}
+
+// ParseMetadataWithOptions is like ParseMetadata but applies the given Options to the textual chunks it
+// parses, as ParseTextualDataWithOptions does.
+func ParseMetadataWithOptions(r io.Reader, opts Options) (*Metadata, error) {
+	return parsePNG(r, opts)
+}
+
+func decodeExifChunk(r io.Reader) (any, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eXIf chunk: %w", err)
+	}
+	return &ExifData{Raw: data}, nil
+}
+
+// decodeTimeChunk decodes a tIME chunk's 7-byte payload (year, month, day, hour, minute, second) into a
+// UTC time.Time.
+// See also: https://www.w3.org/TR/2003/REC-PNG-20031110/#11tIME
+func decodeTimeChunk(r io.Reader) (any, error) {
+	var buf [7]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return nil, fmt.Errorf("failed to read tIME chunk: %w", err)
+	}
+
+	t := time.Date(
+		int(binary.BigEndian.Uint16(buf[0:2])), time.Month(buf[2]), int(buf[3]),
+		int(buf[4]), int(buf[5]), int(buf[6]), 0, time.UTC,
+	)
+	return &t, nil
+}
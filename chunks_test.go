@@ -0,0 +1,81 @@
+package pngtext_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jkawamoto/go-pngtext"
+)
+
+func TestParseMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	if err := pngtext.EncodeChunk(&buf, "IHDR", make([]byte, 13)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "tEXt", []byte("Title\x00hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "eXIf", []byte{0x4D, 0x4D, 0x00, 0x2A}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "IEND", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := pngtext.ParseMetadata(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := md.Find("Title"); v == nil || v.Text != "hello" {
+		t.Errorf("expect %v, got %v", "hello", v)
+	}
+
+	if md.Time == nil {
+		t.Fatal("Time not found")
+	}
+	if expect := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !md.Time.Equal(expect) {
+		t.Errorf("expect %v, got %v", expect, *md.Time)
+	}
+
+	if md.Exif == nil {
+		t.Fatal("Exif not found")
+	}
+	if expect := []byte{0x4D, 0x4D, 0x00, 0x2A}; !bytes.Equal(md.Exif.Raw, expect) {
+		t.Errorf("expect %v, got %v", expect, md.Exif.Raw)
+	}
+}
+
+func TestRegisterChunkHandler(t *testing.T) {
+	pngtext.RegisterChunkHandler("teSt", func(r io.Reader) (any, error) {
+		data, err := io.ReadAll(r)
+		return string(data), err
+	})
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	if err := pngtext.EncodeChunk(&buf, "IHDR", make([]byte, 13)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "teSt", []byte("custom-payload")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "IEND", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	md, err := pngtext.ParseMetadata(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "custom-payload"; md.Chunks["teSt"] != expect {
+		t.Errorf("expect %v, got %v", expect, md.Chunks["teSt"])
+	}
+}
@@ -21,6 +21,24 @@ TextualDataList, which ParseTextualData returns as a result, is a list of Textua
 that helps you to get text data associated with a keyword. This returns TextualData of which keyword is Description:
 
 	res.Find("Description")
+
+WriteTextualData does the opposite: it streams an existing PNG file to a writer while inserting or replacing the
+textual chunks described by a TextualDataList.
+
+tEXt and zTXt chunks are defined to hold ISO-8859-1 text. ParseTextualDataWithOptions with DecodeLatin1 set
+transcodes that text to UTF-8, while still leaving the original bytes available through TextualData.Raw.
+
+For pipelines that need more than a keyword lookup, TextualDataList.Query evaluates a small XPath-like
+language over the list, e.g. res.Query("//text[@keyword='parameters']") or res.Query("//text[lang('en')]").
+
+TextualDataList.StableDiffusionParameters parses the Automatic1111/ComfyUI generation parameters block,
+when present, into the structured sdmeta.Parameters type.
+
+ParseTextualData only returns textual chunks, although it still runs the registered ancillary-chunk
+handlers while reading and fails if one of them does. ParseMetadata parses the same PNG file but also
+returns those ancillary chunks, such as eXIf and tIME, in the returned Metadata, which embeds TextualDataList
+so existing TextualDataList-based code keeps working against it unchanged. RegisterChunkHandler lets
+callers plug in decoders for further chunk types, e.g. iCCP or sPLT.
 */
 package pngtext
 
@@ -33,6 +51,9 @@ import (
 	"hash/crc32"
 	"io"
 	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
 )
 
 const (
@@ -62,6 +83,21 @@ type TextualData struct {
 	// TranslatedKeyword is a translation of the keyword into the language indicated by the language tag.
 	// Only iTXt chunk has this attribute.
 	TranslatedKeyword string
+	// Compressed indicates this item should be written as a compressed chunk, i.e. zTXt instead of tEXt,
+	// or an iTXt chunk with its compression flag set. This attribute is only used by WriteTextualData.
+	Compressed bool
+	// Raw is the text value as originally stored in the chunk, before any encoding conversion. For tEXt
+	// and zTXt chunks this is the raw ISO-8859-1 bytes even when Options.DecodeLatin1 has transcoded Text
+	// to UTF-8; for iTXt chunks it is identical to Text, which is already UTF-8.
+	Raw []byte
+}
+
+// Options configures how ParseTextualDataWithOptions parses textual chunks.
+type Options struct {
+	// DecodeLatin1, when true, transcodes the Text of tEXt and zTXt chunks from their native ISO-8859-1
+	// encoding to UTF-8. iTXt chunks are already UTF-8 and are unaffected. The original bytes remain
+	// available via TextualData.Raw regardless of this option.
+	DecodeLatin1 bool
 }
 
 // TextualDataList is a list of *TextualData that provides Find and implements sort.Interface.
@@ -95,6 +131,23 @@ func (list TextualDataList) Swap(i, j int) {
 
 // ParseTextualData reads PNG data from the given reader and parses textual data.
 func ParseTextualData(r io.Reader) (TextualDataList, error) {
+	return ParseTextualDataWithOptions(r, Options{})
+}
+
+// ParseTextualDataWithOptions reads PNG data from the given reader and parses textual data, as
+// ParseTextualData does, applying the given Options while doing so.
+func ParseTextualDataWithOptions(r io.Reader, opts Options) (TextualDataList, error) {
+	md, err := parsePNG(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return md.TextualDataList, nil
+}
+
+// parsePNG reads PNG data from r and dispatches each chunk: tEXt, zTXt, and iTXt chunks are parsed into
+// the returned Metadata's TextualDataList, while any other chunk type for which a handler has been
+// registered via RegisterChunkHandler is decoded into Metadata.Chunks. Every other chunk is discarded.
+func parsePNG(r io.Reader, opts Options) (*Metadata, error) {
 	buf := make([]byte, bufSize)
 	if _, err := io.ReadFull(r, buf[:len(pngHeader)]); err != nil {
 		return nil, err
@@ -103,7 +156,7 @@ func ParseTextualData(r io.Reader) (TextualDataList, error) {
 		return nil, ErrNotPngData
 	}
 
-	var res []*TextualData
+	md := &Metadata{Chunks: map[string]any{}}
 	for {
 		if _, err := io.ReadFull(r, buf[:lengthSize]); err != nil {
 			return nil, err
@@ -119,29 +172,35 @@ func ParseTextualData(r io.Reader) (TextualDataList, error) {
 		data := bufio.NewReader(io.TeeReader(io.LimitReader(r, size), crc))
 		switch chunkType {
 		case "tEXt":
-			v, err := parseTextData(data)
+			v, err := parseTextData(data, opts)
 			if err != nil {
 				return nil, err
 			}
-			res = append(res, v)
+			md.TextualDataList = append(md.TextualDataList, v)
 
 		case "zTXt":
-			v, err := parseCompressedTextData(data)
+			v, err := parseCompressedTextData(data, opts)
 			if err != nil {
 				return nil, err
 			}
-			res = append(res, v)
+			md.TextualDataList = append(md.TextualDataList, v)
 
 		case "iTXt":
 			v, err := parseInternationalTextData(data)
 			if err != nil {
 				return nil, err
 			}
-			res = append(res, v)
+			md.TextualDataList = append(md.TextualDataList, v)
 
 		default:
-			_, err := io.Copy(io.Discard, data)
-			if err != nil {
+			if h, ok := chunkHandler(chunkType); ok {
+				v, err := h(data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode %s chunk: %w", chunkType, err)
+				}
+				md.Chunks[chunkType] = v
+			}
+			if _, err := io.Copy(io.Discard, data); err != nil {
 				return nil, err
 			}
 		}
@@ -155,7 +214,9 @@ func ParseTextualData(r io.Reader) (TextualDataList, error) {
 
 		// check if last chunk is read.
 		if chunkType == "IEND" {
-			return res, nil
+			md.Exif, _ = md.Chunks["eXIf"].(*ExifData)
+			md.Time, _ = md.Chunks["tIME"].(*time.Time)
+			return md, nil
 		}
 	}
 }
@@ -164,7 +225,7 @@ func trimTailingNull(s string) string {
 	return s[:len(s)-1]
 }
 
-func parseTextData(r *bufio.Reader) (*TextualData, error) {
+func parseTextData(r *bufio.Reader, opts Options) (*TextualData, error) {
 	keyword, err := r.ReadString(0)
 	if err != nil {
 		return nil, err
@@ -179,13 +240,19 @@ func parseTextData(r *bufio.Reader) (*TextualData, error) {
 		return nil, fmt.Errorf("failed to read value: %w", err)
 	}
 
+	text, err := decodeLatin1IfRequested(value, opts)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TextualData{
 		Keyword: trimTailingNull(keyword),
-		Text:    value,
+		Text:    text,
+		Raw:     []byte(value),
 	}, nil
 }
 
-func parseCompressedTextData(r *bufio.Reader) (*TextualData, error) {
+func parseCompressedTextData(r *bufio.Reader, opts Options) (*TextualData, error) {
 	keyword, err := r.ReadString(0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read keyword: %w", err)
@@ -208,9 +275,15 @@ func parseCompressedTextData(r *bufio.Reader) (*TextualData, error) {
 		return nil, fmt.Errorf("failed to read value: %w", err)
 	}
 
+	text, err := decodeLatin1IfRequested(string(data), opts)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TextualData{
 		Keyword: trimTailingNull(keyword),
-		Text:    string(data),
+		Text:    text,
+		Raw:     data,
 	}, nil
 }
 
@@ -258,7 +331,22 @@ func parseInternationalTextData(r *bufio.Reader) (*TextualData, error) {
 	return &TextualData{
 		Keyword:           trimTailingNull(keyword),
 		Text:              string(data),
+		Raw:               data,
 		LanguageTag:       trimTailingNull(lang),
 		TranslatedKeyword: trimTailingNull(translatedKeyword),
 	}, nil
 }
+
+// decodeLatin1IfRequested transcodes s from ISO-8859-1 to UTF-8 when opts.DecodeLatin1 is set, and
+// returns s unchanged otherwise.
+func decodeLatin1IfRequested(s string, opts Options) (string, error) {
+	if !opts.DecodeLatin1 {
+		return s, nil
+	}
+
+	text, err := charmap.ISO8859_1.NewDecoder().String(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode latin-1 text: %w", err)
+	}
+	return text, nil
+}
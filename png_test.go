@@ -1,6 +1,7 @@
 package pngtext_test
 
 import (
+	"bytes"
 	"os"
 	"reflect"
 	"strings"
@@ -153,6 +154,26 @@ func TestParseTextData(t *testing.T) {
 	}
 }
 
+func TestParseTextualDataWithOptions_decodeLatin1(t *testing.T) {
+	src := buildTestPNGWithText(t, "Title", "caf\xe9")
+
+	res, err := pngtext.ParseTextualDataWithOptions(bytes.NewReader(src), pngtext.Options{DecodeLatin1: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := res.Find("Title")
+	if v == nil {
+		t.Fatal("Title tag not found")
+	}
+	if expect := "café"; v.Text != expect {
+		t.Errorf("expect %v, got %v", expect, v.Text)
+	}
+	if expect := "caf\xe9"; string(v.Raw) != expect {
+		t.Errorf("expect %v, got %v", []byte(expect), v.Raw)
+	}
+}
+
 func TestParseNotPNGData(t *testing.T) {
 	r, err := os.Open("LICENSE")
 	if err != nil {
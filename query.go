@@ -0,0 +1,112 @@
+// query.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+package pngtext
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/xpath"
+)
+
+// langFuncPattern matches a call to the XPath lang() function, e.g. lang('en') or lang("en"), which the
+// antchfx/xpath evaluator this package relies on does not implement.
+var langFuncPattern = regexp.MustCompile(`\blang\(\s*(['"])([^'"]*)['"]\s*\)`)
+
+// rewriteLangFunction rewrites every lang(...) call in expr into the equivalent @lang attribute
+// comparison against the context node, so the standard XPath lang() syntax works even though
+// antchfx/xpath doesn't implement the function itself. Unlike real XPath lang(), this only compares the
+// context node's own lang attribute; it does not check ancestors or match language sub-tags.
+//
+// A match is only rewritten when it starts outside any quoted string literal already open in expr, so
+// text that merely contains the substring "lang(...)" inside an unrelated string, e.g.
+// contains(., "lang('fake')"), is left untouched instead of being corrupted into a different predicate.
+func rewriteLangFunction(expr string) string {
+	matches := langFuncPattern.FindAllStringSubmatchIndex(expr, -1)
+	if matches == nil {
+		return expr
+	}
+
+	inQuote := quotedRanges(expr)
+
+	var out strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if inQuote[start] {
+			continue
+		}
+		quote, tag := expr[m[2]:m[3]], expr[m[4]:m[5]]
+		out.WriteString(expr[last:start])
+		out.WriteString("@lang=" + quote + tag + quote)
+		last = end
+	}
+	out.WriteString(expr[last:])
+	return out.String()
+}
+
+// quotedRanges reports, for every byte offset in expr, whether it lies inside a quoted string literal
+// that was already open at that point. A quote character only opens or closes a literal when no other
+// literal is currently open, so a "'" inside a "..." literal (or vice versa) doesn't toggle anything.
+func quotedRanges(expr string) []bool {
+	inQuote := make([]bool, len(expr)+1)
+	var quoteChar byte
+	for i := 0; i < len(expr); i++ {
+		inQuote[i] = quoteChar != 0
+		switch c := expr[i]; {
+		case quoteChar == 0 && (c == '\'' || c == '"'):
+			quoteChar = c
+		case quoteChar != 0 && c == quoteChar:
+			quoteChar = 0
+		}
+	}
+	return inQuote
+}
+
+// Query evaluates an XPath-like expr against list and returns every TextualData whose <text> node it
+// selects. Each item is exposed as a <text> node with keyword, lang, and translatedkeyword attributes and
+// the item's Text as its string value, so expressions such as `//text[@keyword='parameters']`,
+// `//text[lang('en')]`, or `//text[contains(., 'seed:')]` work as expected. lang(...) is translated into
+// an @lang comparison before the expression reaches the underlying XPath evaluator; see
+// rewriteLangFunction for the limits of that translation.
+func (list TextualDataList) Query(expr string) ([]*TextualData, error) {
+	exp, err := xpath.Compile(rewriteLangFunction(expr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile query %q: %w", expr, err)
+	}
+
+	root, index := newQueryTree(list)
+	nav := &queryNavigator{curr: root, root: root, attribute: -1}
+
+	var res []*TextualData
+	iter := exp.Select(nav)
+	for iter.MoveNext() {
+		cur, ok := iter.Current().(*queryNavigator)
+		if !ok {
+			continue
+		}
+		if v, ok := index[cur.curr]; ok {
+			res = append(res, v)
+		}
+	}
+	return res, nil
+}
+
+// QueryOne is like Query but returns only the first match, or nil if expr selects nothing.
+func (list TextualDataList) QueryOne(expr string) (*TextualData, error) {
+	res, err := list.Query(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res[0], nil
+}
@@ -0,0 +1,77 @@
+package pngtext_test
+
+import (
+	"testing"
+
+	"github.com/jkawamoto/go-pngtext"
+)
+
+func TestTextualDataList_Query(t *testing.T) {
+	list := pngtext.TextualDataList{
+		{Keyword: "parameters", Text: "seed: 1234"},
+		{Keyword: "Description", Text: "hello world", LanguageTag: "en"},
+		{Keyword: "Description", Text: "bonjour", LanguageTag: "fr"},
+	}
+
+	res, err := list.Query("//text[@keyword='parameters']")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0] != list[0] {
+		t.Errorf("expect %v, got %v", list[0], res)
+	}
+
+	res, err = list.Query("//text[@lang='en']")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0] != list[1] {
+		t.Errorf("expect %v, got %v", list[1], res)
+	}
+
+	res, err = list.Query("//text[lang('fr')]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0] != list[2] {
+		t.Errorf("expect %v, got %v", list[2], res)
+	}
+
+	res, err = list.Query("//text[contains(., 'seed:')]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0] != list[0] {
+		t.Errorf("expect %v, got %v", list[0], res)
+	}
+
+	res, err = list.Query(`//text[lang('fr') and not(contains(., "lang('fake')"))]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0] != list[2] {
+		t.Errorf("expect %v, got %v", list[2], res)
+	}
+}
+
+func TestTextualDataList_QueryOne(t *testing.T) {
+	list := pngtext.TextualDataList{
+		{Keyword: "Description", Text: "hello world", LanguageTag: "en"},
+	}
+
+	v, err := list.QueryOne("//text[@keyword='Description']")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != list[0] {
+		t.Errorf("expect %v, got %v", list[0], v)
+	}
+
+	v, err = list.QueryOne("//text[@keyword='Missing']")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != nil {
+		t.Errorf("expect %v, got %v", nil, v)
+	}
+}
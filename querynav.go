@@ -0,0 +1,194 @@
+// querynav.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+package pngtext
+
+import "github.com/antchfx/xpath"
+
+// queryNodeType identifies the kind of node in the tree built for Query and QueryOne.
+type queryNodeType int
+
+const (
+	queryRootNode queryNodeType = iota
+	queryElementNode
+	queryTextNode
+)
+
+type queryAttr struct {
+	Name, Value string
+}
+
+// queryNode is a node of the small in-memory tree that TextualDataList.Query evaluates XPath expressions
+// against: a root with one <text> element per TextualData, each holding that item's attributes and a
+// single text child with its Text value.
+type queryNode struct {
+	Type queryNodeType
+	Data string
+	Attr []queryAttr
+
+	Parent, FirstChild, LastChild, PrevSibling, NextSibling *queryNode
+}
+
+func appendQueryChild(parent, child *queryNode) {
+	child.Parent = parent
+	if parent.FirstChild == nil {
+		parent.FirstChild = child
+	} else {
+		parent.LastChild.NextSibling = child
+		child.PrevSibling = parent.LastChild
+	}
+	parent.LastChild = child
+}
+
+// newQueryTree builds the node tree Query and QueryOne evaluate expressions against, along with an index
+// that maps each <text> element back to the TextualData it was built from.
+func newQueryTree(list TextualDataList) (*queryNode, map[*queryNode]*TextualData) {
+	root := &queryNode{Type: queryRootNode}
+	index := make(map[*queryNode]*TextualData, len(list))
+
+	for _, v := range list {
+		el := &queryNode{
+			Type: queryElementNode,
+			Data: "text",
+			Attr: []queryAttr{
+				{Name: "keyword", Value: v.Keyword},
+				{Name: "lang", Value: v.LanguageTag},
+				{Name: "translatedkeyword", Value: v.TranslatedKeyword},
+			},
+		}
+		appendQueryChild(el, &queryNode{Type: queryTextNode, Data: v.Text})
+		appendQueryChild(root, el)
+		index[el] = v
+	}
+
+	return root, index
+}
+
+// queryNavigator implements xpath.NodeNavigator over a queryNode tree.
+type queryNavigator struct {
+	curr, root *queryNode
+	attribute  int
+}
+
+func (n *queryNavigator) NodeType() xpath.NodeType {
+	switch n.curr.Type {
+	case queryRootNode:
+		return xpath.RootNode
+	case queryTextNode:
+		return xpath.TextNode
+	default:
+		if n.attribute != -1 {
+			return xpath.AttributeNode
+		}
+		return xpath.ElementNode
+	}
+}
+
+func (n *queryNavigator) LocalName() string {
+	if n.attribute != -1 {
+		return n.curr.Attr[n.attribute].Name
+	}
+	return n.curr.Data
+}
+
+func (n *queryNavigator) Prefix() string {
+	return ""
+}
+
+func (n *queryNavigator) Value() string {
+	if n.attribute != -1 {
+		return n.curr.Attr[n.attribute].Value
+	}
+	switch n.curr.Type {
+	case queryTextNode:
+		return n.curr.Data
+	case queryElementNode:
+		if n.curr.FirstChild != nil {
+			return n.curr.FirstChild.Data
+		}
+	}
+	return ""
+}
+
+func (n *queryNavigator) Copy() xpath.NodeNavigator {
+	c := *n
+	return &c
+}
+
+func (n *queryNavigator) MoveToRoot() {
+	n.curr = n.root
+	n.attribute = -1
+}
+
+func (n *queryNavigator) MoveToParent() bool {
+	if n.attribute != -1 {
+		n.attribute = -1
+		return true
+	}
+	if n.curr.Parent == nil {
+		return false
+	}
+	n.curr = n.curr.Parent
+	return true
+}
+
+func (n *queryNavigator) MoveToNextAttribute() bool {
+	if n.attribute >= len(n.curr.Attr)-1 {
+		return false
+	}
+	n.attribute++
+	return true
+}
+
+func (n *queryNavigator) MoveToChild() bool {
+	if n.attribute != -1 || n.curr.FirstChild == nil {
+		return false
+	}
+	n.curr = n.curr.FirstChild
+	return true
+}
+
+func (n *queryNavigator) MoveToFirst() bool {
+	if n.attribute != -1 || n.curr.PrevSibling == nil {
+		return false
+	}
+	for n.curr.PrevSibling != nil {
+		n.curr = n.curr.PrevSibling
+	}
+	return true
+}
+
+func (n *queryNavigator) MoveToNext() bool {
+	if n.attribute != -1 || n.curr.NextSibling == nil {
+		return false
+	}
+	n.curr = n.curr.NextSibling
+	return true
+}
+
+func (n *queryNavigator) MoveToPrevious() bool {
+	if n.attribute != -1 || n.curr.PrevSibling == nil {
+		return false
+	}
+	n.curr = n.curr.PrevSibling
+	return true
+}
+
+func (n *queryNavigator) MoveTo(other xpath.NodeNavigator) bool {
+	node, ok := other.(*queryNavigator)
+	if !ok || node.root != n.root {
+		return false
+	}
+	n.curr = node.curr
+	n.attribute = node.attribute
+	return true
+}
+
+func (n *queryNavigator) String() string {
+	return n.Value()
+}
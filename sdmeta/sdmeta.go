@@ -0,0 +1,199 @@
+// sdmeta.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+/*
+Package sdmeta parses the de-facto Automatic1111/ComfyUI "parameters" text block that Stable Diffusion
+tools store in a PNG's parameters, Comment, or Description textual chunk, turning it into a typed
+Parameters value:
+
+	res, _ := pngtext.ParseTextualData(r)
+	params, _ := sdmeta.Parse(res.Find("parameters").Text)
+*/
+package sdmeta
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parameters is the structured form of a Stable Diffusion generation parameters block.
+type Parameters struct {
+	// Prompt is the positive prompt used to generate the image.
+	Prompt string
+	// NegativePrompt is the negative prompt, taken from the "Negative prompt:" line.
+	NegativePrompt string
+	// Steps is the number of sampling steps.
+	Steps int
+	// Sampler is the name of the sampler used, e.g. "Euler a".
+	Sampler string
+	// CFGScale is the classifier-free guidance scale.
+	CFGScale float64
+	// Seed is the generation seed.
+	Seed int64
+	// Size is the image size, formatted as "<width>x<height>".
+	Size string
+	// ModelHash is the checksum of the checkpoint used to generate the image.
+	ModelHash string
+	// Model is the name of the checkpoint used to generate the image.
+	Model string
+	// Extra holds any key/value pair from the settings line that isn't one of the fields above.
+	Extra map[string]string
+}
+
+// knownKeys maps the settings-line key, lowercased, to the setter that fills in the matching field.
+var knownKeys = map[string]func(*Parameters, string) error{
+	"steps": func(p *Parameters, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("failed to parse Steps: %w", err)
+		}
+		p.Steps = n
+		return nil
+	},
+	"sampler": func(p *Parameters, v string) error {
+		p.Sampler = v
+		return nil
+	},
+	"cfg scale": func(p *Parameters, v string) error {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse CFG scale: %w", err)
+		}
+		p.CFGScale = n
+		return nil
+	},
+	"seed": func(p *Parameters, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse Seed: %w", err)
+		}
+		p.Seed = n
+		return nil
+	},
+	"size": func(p *Parameters, v string) error {
+		p.Size = v
+		return nil
+	},
+	"model hash": func(p *Parameters, v string) error {
+		p.ModelHash = v
+		return nil
+	},
+	"model": func(p *Parameters, v string) error {
+		p.Model = v
+		return nil
+	},
+}
+
+// Parse parses a Stable Diffusion generation parameters block as stored under the parameters, Comment, or
+// Description keyword. The prompt is everything up to the "Negative prompt:" line, if present, and up to
+// the trailing comma-separated "Key: value" settings line. Quoted values in the settings line may contain
+// commas.
+func Parse(s string) (*Parameters, error) {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+
+	settingsIdx := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if looksLikeSettingsLine(lines[i]) {
+			settingsIdx = i
+			break
+		}
+	}
+
+	negativeIdx := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Negative prompt:") {
+			negativeIdx = i
+			break
+		}
+	}
+
+	promptEnd := len(lines)
+	if negativeIdx != -1 {
+		promptEnd = negativeIdx
+	} else if settingsIdx != -1 {
+		promptEnd = settingsIdx
+	}
+
+	res := &Parameters{
+		Prompt: strings.TrimSpace(strings.Join(lines[:promptEnd], "\n")),
+		Extra:  map[string]string{},
+	}
+
+	if negativeIdx != -1 {
+		negativeEnd := len(lines)
+		if settingsIdx != -1 {
+			negativeEnd = settingsIdx
+		}
+		negative := strings.Join(lines[negativeIdx:negativeEnd], "\n")
+		res.NegativePrompt = strings.TrimSpace(strings.TrimPrefix(negative, "Negative prompt:"))
+	}
+
+	if settingsIdx != -1 {
+		for _, field := range splitSettingsLine(lines[settingsIdx]) {
+			key, value, ok := strings.Cut(field, ":")
+			if !ok {
+				continue
+			}
+			key = strings.TrimSpace(key)
+			value = unquote(strings.TrimSpace(value))
+
+			if setter, known := knownKeys[strings.ToLower(key)]; known {
+				if err := setter(res, value); err != nil {
+					return nil, err
+				}
+			} else {
+				res.Extra[key] = value
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// looksLikeSettingsLine reports whether line is the comma-separated "Key: value, ..." settings line,
+// identified by the "Steps:" field that Automatic1111 and ComfyUI always include.
+func looksLikeSettingsLine(line string) bool {
+	for _, field := range splitSettingsLine(line) {
+		key, _, ok := strings.Cut(field, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "steps") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSettingsLine splits a settings line on commas that are not inside double quotes.
+func splitSettingsLine(line string) []string {
+	var fields []string
+	var buf strings.Builder
+	inQuotes := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			buf.WriteRune(r)
+		case r == ',' && !inQuotes:
+			fields = append(fields, strings.TrimSpace(buf.String()))
+			buf.Reset()
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	if buf.Len() > 0 {
+		fields = append(fields, strings.TrimSpace(buf.String()))
+	}
+	return fields
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
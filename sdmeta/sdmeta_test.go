@@ -0,0 +1,66 @@
+package sdmeta_test
+
+import (
+	"testing"
+
+	"github.com/jkawamoto/go-pngtext/sdmeta"
+)
+
+func TestParse(t *testing.T) {
+	const input = `a photo of a cat, highly detailed
+Negative prompt: blurry, low quality
+Steps: 20, Sampler: Euler a, CFG scale: 7.5, Seed: 123456, Size: 512x768, Model hash: abcdef12, Model: myModel, Hires resize: "1024,1536"`
+
+	res, err := sdmeta.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "a photo of a cat, highly detailed"; res.Prompt != expect {
+		t.Errorf("expect %v, got %v", expect, res.Prompt)
+	}
+	if expect := "blurry, low quality"; res.NegativePrompt != expect {
+		t.Errorf("expect %v, got %v", expect, res.NegativePrompt)
+	}
+	if res.Steps != 20 {
+		t.Errorf("expect %v, got %v", 20, res.Steps)
+	}
+	if expect := "Euler a"; res.Sampler != expect {
+		t.Errorf("expect %v, got %v", expect, res.Sampler)
+	}
+	if res.CFGScale != 7.5 {
+		t.Errorf("expect %v, got %v", 7.5, res.CFGScale)
+	}
+	if res.Seed != 123456 {
+		t.Errorf("expect %v, got %v", 123456, res.Seed)
+	}
+	if expect := "512x768"; res.Size != expect {
+		t.Errorf("expect %v, got %v", expect, res.Size)
+	}
+	if expect := "abcdef12"; res.ModelHash != expect {
+		t.Errorf("expect %v, got %v", expect, res.ModelHash)
+	}
+	if expect := "myModel"; res.Model != expect {
+		t.Errorf("expect %v, got %v", expect, res.Model)
+	}
+	if expect := "1024,1536"; res.Extra["Hires resize"] != expect {
+		t.Errorf("expect %v, got %v", expect, res.Extra["Hires resize"])
+	}
+}
+
+func TestParse_noNegativePrompt(t *testing.T) {
+	const input = `a photo of a cat
+Steps: 20, Sampler: Euler a, CFG scale: 7, Seed: 1, Size: 512x512, Model hash: abc, Model: m`
+
+	res, err := sdmeta.Parse(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "a photo of a cat"; res.Prompt != expect {
+		t.Errorf("expect %v, got %v", expect, res.Prompt)
+	}
+	if res.NegativePrompt != "" {
+		t.Errorf("expect %v, got %v", "", res.NegativePrompt)
+	}
+}
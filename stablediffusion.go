@@ -0,0 +1,34 @@
+// stablediffusion.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+package pngtext
+
+import (
+	"fmt"
+
+	"github.com/jkawamoto/go-pngtext/sdmeta"
+)
+
+// stableDiffusionKeywords lists the keywords, in order of preference, under which tools following the
+// Automatic1111/ComfyUI convention store their generation parameters block.
+var stableDiffusionKeywords = []string{"parameters", "Comment", "Description"}
+
+// ErrStableDiffusionParametersNotFound is returned by TextualDataList.StableDiffusionParameters when none
+// of the keywords used by Stable Diffusion tools are present in the list.
+var ErrStableDiffusionParametersNotFound = fmt.Errorf("no stable diffusion parameters found")
+
+// StableDiffusionParameters looks up the generation parameters block stored by Automatic1111/ComfyUI under
+// the parameters, Comment, or Description keyword, and parses it with sdmeta.Parse.
+func (list TextualDataList) StableDiffusionParameters() (*sdmeta.Parameters, error) {
+	for _, keyword := range stableDiffusionKeywords {
+		if v := list.Find(keyword); v != nil {
+			return sdmeta.Parse(v.Text)
+		}
+	}
+	return nil, ErrStableDiffusionParametersNotFound
+}
@@ -0,0 +1,33 @@
+package pngtext_test
+
+import (
+	"testing"
+
+	"github.com/jkawamoto/go-pngtext"
+)
+
+func TestTextualDataList_StableDiffusionParameters(t *testing.T) {
+	list := pngtext.TextualDataList{
+		{
+			Keyword: "parameters",
+			Text:    "a cat\nSteps: 20, Sampler: Euler a, CFG scale: 7, Seed: 1, Size: 512x512, Model hash: abc, Model: m",
+		},
+	}
+
+	res, err := list.StableDiffusionParameters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "a cat"; res.Prompt != expect {
+		t.Errorf("expect %v, got %v", expect, res.Prompt)
+	}
+}
+
+func TestTextualDataList_StableDiffusionParameters_notFound(t *testing.T) {
+	list := pngtext.TextualDataList{{Keyword: "Title", Text: "no metadata here"}}
+
+	_, err := list.StableDiffusionParameters()
+	if err != pngtext.ErrStableDiffusionParametersNotFound {
+		t.Errorf("expect %v, got %v", pngtext.ErrStableDiffusionParametersNotFound, err)
+	}
+}
@@ -0,0 +1,176 @@
+// stream.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+package pngtext
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// TextualDataStream defines attributes of a chunk delivered by ParseTextualDataFunc. It mirrors
+// TextualData except the text value is exposed as an io.Reader instead of a string, so the payload of
+// large zTXt and iTXt chunks never has to be buffered in memory.
+type TextualDataStream struct {
+	// Keyword of the textual data.
+	Keyword string
+	// Text is a reader over the value associated with the keyword. For zTXt and iTXt chunks, it
+	// transparently decompresses the payload as it is read.
+	Text io.Reader
+	// LanguageTag indicates the human language used by the translated keyword and the text.
+	// Only iTXt chunk has this attribute.
+	LanguageTag string
+	// TranslatedKeyword is a translation of the keyword into the language indicated by the language tag.
+	// Only iTXt chunk has this attribute.
+	TranslatedKeyword string
+}
+
+// ParseTextualDataFunc reads PNG data from the given reader and invokes fn with each textual chunk as it
+// is parsed, instead of collecting them all into a TextualDataList. fn may return io.EOF to stop parsing
+// early without it being treated as an error.
+func ParseTextualDataFunc(r io.Reader, fn func(*TextualDataStream) error) error {
+	buf := make([]byte, bufSize)
+	if _, err := io.ReadFull(r, buf[:len(pngHeader)]); err != nil {
+		return err
+	}
+	if string(buf[:len(pngHeader)]) != pngHeader {
+		return ErrNotPngData
+	}
+
+	for {
+		if _, err := io.ReadFull(r, buf[:lengthSize]); err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(buf[:lengthSize]))
+
+		crc := crc32.NewIEEE()
+		if _, err := io.ReadFull(io.TeeReader(r, crc), buf[:typeSize]); err != nil {
+			return err
+		}
+		chunkType := string(buf[:typeSize])
+
+		data := bufio.NewReader(io.TeeReader(io.LimitReader(r, size), crc))
+		var err error
+		switch chunkType {
+		case "tEXt":
+			err = parseTextDataFunc(data, fn)
+		case "zTXt":
+			err = parseCompressedTextDataFunc(data, fn)
+		case "iTXt":
+			err = parseInternationalTextDataFunc(data, fn)
+		}
+
+		// Drain whatever fn didn't read so the CRC below covers the whole chunk.
+		if _, discardErr := io.Copy(io.Discard, data); discardErr != nil {
+			return discardErr
+		}
+
+		if _, crcErr := io.ReadFull(r, buf[:crcSize]); crcErr != nil {
+			return crcErr
+		} else if !bytes.Equal(buf[:crcSize], crc.Sum(nil)) {
+			return ErrCRC
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		if chunkType == "IEND" {
+			return nil
+		}
+	}
+}
+
+func parseTextDataFunc(r *bufio.Reader, fn func(*TextualDataStream) error) error {
+	keyword, err := r.ReadString(0)
+	if err != nil {
+		return fmt.Errorf("failed to read keyword: %w", err)
+	}
+
+	return fn(&TextualDataStream{
+		Keyword: trimTailingNull(keyword),
+		Text:    r,
+	})
+}
+
+func parseCompressedTextDataFunc(r *bufio.Reader, fn func(*TextualDataStream) error) error {
+	keyword, err := r.ReadString(0)
+	if err != nil {
+		return fmt.Errorf("failed to read keyword: %w", err)
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read compression type: %w", err)
+	} else if b != 0 {
+		return ErrUnsupportedCompressionType
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to decompress value: %w", err)
+	}
+
+	return fn(&TextualDataStream{
+		Keyword: trimTailingNull(keyword),
+		Text:    zr,
+	})
+}
+
+func parseInternationalTextDataFunc(r *bufio.Reader, fn func(*TextualDataStream) error) error {
+	keyword, err := r.ReadString(0)
+	if err != nil {
+		return fmt.Errorf("failed to read keyword: %w", err)
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read compression flag: %w", err)
+	}
+	compression := b == 1
+
+	b, err = r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read compression type: %w", err)
+	} else if compression && b != 0 {
+		return ErrUnsupportedCompressionType
+	}
+
+	lang, err := r.ReadString(0)
+	if err != nil {
+		return fmt.Errorf("failed to read language tag: %w", err)
+	}
+
+	translatedKeyword, err := r.ReadString(0)
+	if err != nil {
+		return fmt.Errorf("failed to read translated keyword: %w", err)
+	}
+
+	var reader io.Reader = r
+	if compression {
+		reader, err = zlib.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("failed to decompress value: %w", err)
+		}
+	}
+
+	return fn(&TextualDataStream{
+		Keyword:           trimTailingNull(keyword),
+		Text:              reader,
+		LanguageTag:       trimTailingNull(lang),
+		TranslatedKeyword: trimTailingNull(translatedKeyword),
+	})
+}
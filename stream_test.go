@@ -0,0 +1,60 @@
+package pngtext_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/jkawamoto/go-pngtext"
+)
+
+func TestParseTextualDataFunc(t *testing.T) {
+	src := buildTestPNGWithText(t, "Title", "hello")
+
+	var got string
+	err := pngtext.ParseTextualDataFunc(bytes.NewReader(src), func(v *pngtext.TextualDataStream) error {
+		data, err := io.ReadAll(v.Text)
+		if err != nil {
+			return err
+		}
+		got = v.Keyword + "=" + string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := "Title=hello"; got != expect {
+		t.Errorf("expect %v, got %v", expect, got)
+	}
+}
+
+func TestParseTextualDataFunc_stopEarly(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	if err := pngtext.EncodeChunk(&buf, "IHDR", make([]byte, 13)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "tEXt", []byte("First\x00one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "tEXt", []byte("Second\x00two")); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "IEND", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var keywords []string
+	err := pngtext.ParseTextualDataFunc(bytes.NewReader(buf.Bytes()), func(v *pngtext.TextualDataStream) error {
+		keywords = append(keywords, v.Keyword)
+		return io.EOF
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expect := []string{"First"}; len(keywords) != len(expect) || keywords[0] != expect[0] {
+		t.Errorf("expect %v, got %v", expect, keywords)
+	}
+}
@@ -0,0 +1,311 @@
+// write.go
+//
+// Copyright (c) 2023 Junpei Kawamoto
+//
+// This software is released under the MIT License.
+//
+// http://opensource.org/licenses/mit-license.php
+
+package pngtext
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidKeyword     = fmt.Errorf("keyword must be 1-79 Latin-1 bytes")
+	ErrInvalidLanguageTag = fmt.Errorf("invalid BCP-47 language tag")
+)
+
+// EncodeChunk writes a single PNG chunk of the given type and data to w, computing its length and CRC.
+func EncodeChunk(w io.Writer, chunkType string, data []byte) error {
+	_, err := w.Write(AppendChunk(nil, chunkType, data))
+	return err
+}
+
+// AppendChunk appends a PNG chunk of the given type and data to buf, computing its length and CRC, and
+// returns the extended buffer. It is the building block EncodeChunk is implemented on top of.
+func AppendChunk(buf []byte, chunkType string, data []byte) []byte {
+	size := make([]byte, lengthSize)
+	binary.BigEndian.PutUint32(size, uint32(len(data)))
+	buf = append(buf, size...)
+
+	crc := crc32.NewIEEE()
+	_, _ = io.WriteString(crc, chunkType)
+	_, _ = crc.Write(data)
+
+	buf = append(buf, chunkType...)
+	buf = append(buf, data...)
+	return append(buf, crc.Sum(nil)...)
+}
+
+// WriteTextualData streams the PNG data read from r to w, inserting or replacing the tEXt, zTXt, and iTXt
+// chunks described by list just before the IEND chunk. A chunk read from r whose identity (see
+// replacementKey) matches an item in list is dropped so the new value takes its place; every other chunk
+// is copied byte-for-byte.
+func WriteTextualData(w io.Writer, r io.Reader, list TextualDataList) error {
+	buf := make([]byte, bufSize)
+	if _, err := io.ReadFull(r, buf[:len(pngHeader)]); err != nil {
+		return err
+	}
+	if string(buf[:len(pngHeader)]) != pngHeader {
+		return ErrNotPngData
+	}
+	if _, err := io.WriteString(w, pngHeader); err != nil {
+		return err
+	}
+
+	pending := make(map[string]struct{}, len(list))
+	for _, v := range list {
+		pending[replacementKey(v)] = struct{}{}
+	}
+
+	for {
+		if _, err := io.ReadFull(r, buf[:lengthSize]); err != nil {
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(buf[:lengthSize]))
+
+		if _, err := io.ReadFull(r, buf[:typeSize]); err != nil {
+			return err
+		}
+		chunkType := string(buf[:typeSize])
+
+		// Only tEXt/zTXt/iTXt and IEND need to be inspected, and IEND is always empty; every other chunk,
+		// including the potentially huge IDAT, is streamed straight through to w without ever holding the
+		// whole chunk in memory, so a bogus chunk-length field can't be used to force a giant allocation.
+		if chunkType != "IEND" && !isTextualChunkType(chunkType) {
+			if err := copyChunk(w, r, buf[:lengthSize], chunkType, size); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(r, buf[:crcSize]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r, size))
+		if err != nil {
+			return err
+		}
+		if int64(len(data)) != size {
+			return io.ErrUnexpectedEOF
+		}
+		if _, err := io.ReadFull(r, buf[:crcSize]); err != nil {
+			return err
+		}
+
+		if chunkType == "IEND" {
+			for _, v := range list {
+				if err := writeTextualDataChunk(w, v); err != nil {
+					return err
+				}
+			}
+			return EncodeChunk(w, chunkType, data)
+		}
+
+		if key, ok := chunkKey(chunkType, data); ok {
+			if _, replacing := pending[key]; replacing {
+				continue
+			}
+		}
+		if err := EncodeChunk(w, chunkType, data); err != nil {
+			return err
+		}
+	}
+}
+
+// isTextualChunkType reports whether chunkType is one WriteTextualData must inspect (and therefore buffer
+// in full) to decide whether it is being replaced.
+func isTextualChunkType(chunkType string) bool {
+	switch chunkType {
+	case "tEXt", "zTXt", "iTXt":
+		return true
+	default:
+		return false
+	}
+}
+
+// copyChunk writes a chunk of the given type and size to w, copying its data straight from r without
+// buffering the whole chunk, and recomputing its CRC on the fly. sizeBuf is scratch space reused across
+// calls to avoid an allocation per chunk.
+func copyChunk(w io.Writer, r io.Reader, sizeBuf []byte, chunkType string, size int64) error {
+	binary.BigEndian.PutUint32(sizeBuf, uint32(size))
+	if _, err := w.Write(sizeBuf); err != nil {
+		return err
+	}
+
+	crc := crc32.NewIEEE()
+	_, _ = io.WriteString(crc, chunkType)
+	if _, err := io.WriteString(w, chunkType); err != nil {
+		return err
+	}
+
+	if n, err := io.Copy(io.MultiWriter(w, crc), io.LimitReader(r, size)); err != nil {
+		return err
+	} else if n != size {
+		return io.ErrUnexpectedEOF
+	}
+
+	_, err := w.Write(crc.Sum(nil))
+	return err
+}
+
+// replacementKey returns the identity WriteTextualData matches an existing chunk against a replacement
+// from list with: the keyword alone for tEXt/zTXt, and the keyword plus language tag for iTXt, since
+// several iTXt chunks may legitimately share a keyword with different translations.
+func replacementKey(v *TextualData) string {
+	// Chunks read from a PNG carry the keyword as ISO-8859-1 bytes, so compare against that form.
+	keyword, err := encodeLatin1(v.Keyword)
+	if err != nil {
+		keyword = v.Keyword
+	}
+	if v.LanguageTag != "" || v.TranslatedKeyword != "" {
+		return keyword + "\x00" + v.LanguageTag
+	}
+	return keyword
+}
+
+// chunkKey extracts the same identity replacementKey computes, from the raw type and data of a chunk read
+// from a source PNG. It returns false for any chunk type other than tEXt, zTXt, or iTXt.
+func chunkKey(chunkType string, data []byte) (string, bool) {
+	keyword, rest, ok := bytes.Cut(data, []byte{0})
+	if !ok {
+		return "", false
+	}
+
+	switch chunkType {
+	case "tEXt", "zTXt":
+		return string(keyword), true
+
+	case "iTXt":
+		// rest is: compression flag (1 byte) + compression method (1 byte) + language tag\0 + ...
+		if len(rest) < 2 {
+			return string(keyword), true
+		}
+		lang, _, ok := bytes.Cut(rest[2:], []byte{0})
+		if !ok {
+			return string(keyword), true
+		}
+		return string(keyword) + "\x00" + string(lang), true
+
+	default:
+		return "", false
+	}
+}
+
+func writeTextualDataChunk(w io.Writer, v *TextualData) error {
+	chunkType, payload, err := encodeTextualData(v)
+	if err != nil {
+		return err
+	}
+	return EncodeChunk(w, chunkType, payload)
+}
+
+// encodeTextualData picks the chunk type for v and builds its payload: iTXt when a language tag or
+// translated keyword is set, zTXt when Compressed is set, tEXt otherwise. The choice does not depend on
+// Text, so a tEXt or zTXt item whose Text has no ISO-8859-1 representation returns an error.
+func encodeTextualData(v *TextualData) (chunkType string, payload []byte, err error) {
+	// The Keyword field of tEXt/zTXt/iTXt must be Latin-1 like Text, and the 1-79 byte limit is on the
+	// Latin-1-encoded form, not the UTF-8 length of the Go string.
+	keyword, err := encodeLatin1(v.Keyword)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidKeyword, err)
+	}
+	if len(keyword) == 0 || len(keyword) > 79 {
+		return "", nil, ErrInvalidKeyword
+	}
+
+	if v.LanguageTag != "" || v.TranslatedKeyword != "" {
+		return encodeInternationalTextData(v, keyword)
+	}
+	if v.Compressed {
+		return encodeCompressedTextData(v, keyword)
+	}
+	return encodeTextData(v, keyword)
+}
+
+func encodeTextData(v *TextualData, keyword string) (string, []byte, error) {
+	text, err := encodeLatin1(v.Text)
+	if err != nil {
+		return "", nil, err
+	}
+
+	buf := append([]byte(keyword), 0)
+	buf = append(buf, text...)
+	return "tEXt", buf, nil
+}
+
+func encodeCompressedTextData(v *TextualData, keyword string) (string, []byte, error) {
+	text, err := encodeLatin1(v.Text)
+	if err != nil {
+		return "", nil, err
+	}
+
+	compressed, err := zlibCompress(text)
+	if err != nil {
+		return "", nil, err
+	}
+
+	buf := append([]byte(keyword), 0, 0) // keyword\0 + compression method (0, the only one defined)
+	buf = append(buf, compressed...)
+	return "zTXt", buf, nil
+}
+
+// encodeLatin1 transcodes s from UTF-8 to ISO-8859-1, the encoding the PNG spec requires for tEXt and
+// zTXt text, mirroring the decode side in decodeLatin1IfRequested. It errors if s contains a character
+// that has no ISO-8859-1 representation.
+func encodeLatin1(s string) (string, error) {
+	text, err := charmap.ISO8859_1.NewEncoder().String(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode text as latin-1: %w", err)
+	}
+	return text, nil
+}
+
+func encodeInternationalTextData(v *TextualData, keyword string) (string, []byte, error) {
+	if v.LanguageTag != "" {
+		if _, err := language.Parse(v.LanguageTag); err != nil {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidLanguageTag, v.LanguageTag)
+		}
+	}
+
+	text := []byte(v.Text)
+	compressionFlag := byte(0)
+	if v.Compressed {
+		compressionFlag = 1
+		compressed, err := zlibCompress(v.Text)
+		if err != nil {
+			return "", nil, err
+		}
+		text = compressed
+	}
+
+	buf := append([]byte(keyword), 0, compressionFlag, 0)
+	buf = append(buf, v.LanguageTag...)
+	buf = append(buf, 0)
+	buf = append(buf, v.TranslatedKeyword...)
+	buf = append(buf, 0)
+	buf = append(buf, text...)
+	return "iTXt", buf, nil
+}
+
+func zlibCompress(text string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := io.WriteString(zw, text); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,219 @@
+package pngtext_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jkawamoto/go-pngtext"
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestEncodeChunk(t *testing.T) {
+	var buf bytes.Buffer
+	if err := pngtext.EncodeChunk(&buf, "tEXt", []byte("Keyword\x00value")); err != nil {
+		t.Fatal(err)
+	}
+
+	if expect, got := 4+4+len("Keyword\x00value")+4, buf.Len(); got != expect {
+		t.Errorf("expect %v, got %v", expect, got)
+	}
+}
+
+func TestWriteTextualData(t *testing.T) {
+	src := buildTestPNG(t)
+
+	list := pngtext.TextualDataList{
+		{Keyword: "Title", Text: "hello"},
+		{Keyword: "Comment", Text: "compressed", Compressed: true},
+		{Keyword: "Description", Text: "world", LanguageTag: "en", TranslatedKeyword: "Description"},
+	}
+
+	var buf bytes.Buffer
+	if err := pngtext.WriteTextualData(&buf, bytes.NewReader(src), list); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pngtext.ParseTextualData(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := res.Find("Title"); v == nil || v.Text != "hello" {
+		t.Errorf("expect %v, got %v", "hello", v)
+	}
+	if v := res.Find("Comment"); v == nil || v.Text != "compressed" {
+		t.Errorf("expect %v, got %v", "compressed", v)
+	}
+	if v := res.Find("Description"); v == nil || v.Text != "world" || v.LanguageTag != "en" {
+		t.Errorf("expect %v, got %v", "world", v)
+	}
+}
+
+func TestWriteTextualData_replace(t *testing.T) {
+	src := buildTestPNGWithText(t, "Title", "old")
+
+	var buf bytes.Buffer
+	list := pngtext.TextualDataList{{Keyword: "Title", Text: "new"}}
+	if err := pngtext.WriteTextualData(&buf, bytes.NewReader(src), list); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pngtext.ParseTextualData(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 {
+		t.Fatalf("expect 1 item, got %v", len(res))
+	}
+	if v := res.Find("Title"); v == nil || v.Text != "new" {
+		t.Errorf("expect %v, got %v", "new", v)
+	}
+}
+
+func TestWriteTextualData_preservesOtherTranslations(t *testing.T) {
+	var base bytes.Buffer
+	err := pngtext.WriteTextualData(&base, bytes.NewReader(buildTestPNG(t)), pngtext.TextualDataList{
+		{Keyword: "Title", Text: "hello", LanguageTag: "en", TranslatedKeyword: "Title"},
+		{Keyword: "Title", Text: "bonjour", LanguageTag: "fr", TranslatedKeyword: "Title"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	list := pngtext.TextualDataList{{Keyword: "Title", Text: "hi", LanguageTag: "en", TranslatedKeyword: "Title"}}
+	if err := pngtext.WriteTextualData(&out, bytes.NewReader(base.Bytes()), list); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pngtext.ParseTextualData(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 {
+		t.Fatalf("expect 2 items, got %v", len(res))
+	}
+
+	var en, fr *pngtext.TextualData
+	for _, v := range res {
+		switch v.LanguageTag {
+		case "en":
+			en = v
+		case "fr":
+			fr = v
+		}
+	}
+	if en == nil || en.Text != "hi" {
+		t.Errorf("expect %v, got %v", "hi", en)
+	}
+	if fr == nil || fr.Text != "bonjour" {
+		t.Errorf("expect %v, got %v", "bonjour", fr)
+	}
+}
+
+func TestWriteTextualData_latin1(t *testing.T) {
+	src := buildTestPNG(t)
+
+	var buf bytes.Buffer
+	list := pngtext.TextualDataList{{Keyword: "Title", Text: "café"}}
+	if err := pngtext.WriteTextualData(&buf, bytes.NewReader(src), list); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pngtext.ParseTextualDataWithOptions(bytes.NewReader(buf.Bytes()), pngtext.Options{DecodeLatin1: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := res.Find("Title"); v == nil || v.Text != "café" {
+		t.Errorf("expect %v, got %v", "café", v)
+	}
+}
+
+func TestWriteTextualData_keywordLatin1(t *testing.T) {
+	src := buildTestPNG(t)
+
+	var buf bytes.Buffer
+	list := pngtext.TextualDataList{{Keyword: "Café", Text: "hello"}}
+	if err := pngtext.WriteTextualData(&buf, bytes.NewReader(src), list); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pngtext.ParseTextualData(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expect 1 item, got %v", len(res))
+	}
+
+	// Keyword is written as raw ISO-8859-1 bytes, the same as Text, so it must be decoded to compare.
+	keyword, err := charmap.ISO8859_1.NewDecoder().String(res[0].Keyword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyword != "Café" {
+		t.Errorf("expect %v, got %v", "Café", keyword)
+	}
+}
+
+func TestWriteTextualData_streamsLargeChunks(t *testing.T) {
+	idat := bytes.Repeat([]byte("x"), 1<<20)
+
+	var src bytes.Buffer
+	src.WriteString("\x89PNG\r\n\x1a\n")
+	if err := pngtext.EncodeChunk(&src, "IHDR", make([]byte, 13)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&src, "IDAT", idat); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&src, "IEND", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	list := pngtext.TextualDataList{{Keyword: "Title", Text: "hello"}}
+	if err := pngtext.WriteTextualData(&buf, bytes.NewReader(src.Bytes()), list); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := pngtext.ParseTextualData(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := res.Find("Title"); v == nil || v.Text != "hello" {
+		t.Errorf("expect %v, got %v", "hello", v)
+	}
+}
+
+func buildTestPNG(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	if err := pngtext.EncodeChunk(&buf, "IHDR", make([]byte, 13)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "IEND", nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func buildTestPNGWithText(t *testing.T, keyword, value string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	buf.WriteString("\x89PNG\r\n\x1a\n")
+	if err := pngtext.EncodeChunk(&buf, "IHDR", make([]byte, 13)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "tEXt", []byte(keyword+"\x00"+value)); err != nil {
+		t.Fatal(err)
+	}
+	if err := pngtext.EncodeChunk(&buf, "IEND", nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}